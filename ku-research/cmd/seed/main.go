@@ -0,0 +1,147 @@
+// Command seed fills the configured database with fake users, workspace
+// membership, and research papers so developers can exercise and
+// benchmark the server (in particular models.GetAccessiblePapers) against
+// a realistic volume of data instead of the single sample paper main.go
+// starts with.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"ku-research/models"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+// visibilityOptions mirrors the options ResearchPaper.PublicOption
+// accepts; an empty string stands for a private, owner-only paper.
+var visibilityOptions = []string{"", "workspace", "site", "everyone"}
+
+// classificationPool is sampled from to build each paper's Classifications.
+var classificationPool = []string{
+	"Quantum Computing", "Theoretical Physics", "Cryptography", "Machine Learning",
+	"Genomics", "Climate Science", "Robotics", "Neuroscience", "Economics",
+	"Astrophysics", "Materials Science", "Epidemiology",
+}
+
+// randomClassifications picks n distinct labels from classificationPool.
+func randomClassifications(rng *rand.Rand, n int) []string {
+	picked := make(map[string]bool, n)
+	classifications := make([]string, 0, n)
+	for len(classifications) < n && len(classifications) < len(classificationPool) {
+		label := classificationPool[rng.Intn(len(classificationPool))]
+		if picked[label] {
+			continue
+		}
+		picked[label] = true
+		classifications = append(classifications, label)
+	}
+	return classifications
+}
+
+func main() {
+	users := flag.Int("users", 50, "number of distinct user IDs to distribute across site/workspace membership and papers")
+	workspaces := flag.Int("workspaces", 10, "number of workspaces to generate membership for")
+	papers := flag.Int("papers", 1000, "number of research papers to generate")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "RNG seed; pass the same value again to reproduce a run")
+	flag.Parse()
+
+	if err := models.Init(); err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v\n", err)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	gofakeit.Seed(*seed)
+
+	log.Printf("🌱 Seeding with seed=%d users=%d workspaces=%d papers=%d\n", *seed, *users, *workspaces, *papers)
+
+	siteUsers, err := seedSiteUsers(*users)
+	if err != nil {
+		log.Fatalf("❌ Failed to seed site users: %v\n", err)
+	}
+
+	workspaceUsers, err := seedWorkspaceUsers(rng, *users, *workspaces)
+	if err != nil {
+		log.Fatalf("❌ Failed to seed workspace users: %v\n", err)
+	}
+
+	paperCount, err := seedPapers(rng, *users, *workspaces, *papers)
+	if err != nil {
+		log.Fatalf("❌ Failed to seed papers: %v\n", err)
+	}
+
+	fmt.Println("✅ Seed complete:")
+	fmt.Printf("  site_users:      %d\n", siteUsers)
+	fmt.Printf("  workspace_users: %d\n", workspaceUsers)
+	fmt.Printf("  papers:          %d\n", paperCount)
+}
+
+// seedSiteUsers makes every userID from 1..users a member of the site.
+func seedSiteUsers(users int) (int, error) {
+	rows := make([]models.SiteUser, 0, users)
+	for userID := 1; userID <= users; userID++ {
+		rows = append(rows, models.SiteUser{UserID: userID})
+	}
+	if err := models.DB.CreateInBatches(rows, 500).Error; err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// seedWorkspaceUsers joins each user to a random 1-3 of the workspaces.
+func seedWorkspaceUsers(rng *rand.Rand, users, workspaces int) (int, error) {
+	var rows []models.WorkspaceUser
+	for userID := 1; userID <= users; userID++ {
+		joinCount := rng.Intn(3) + 1
+		joined := make(map[int]bool, joinCount)
+		for i := 0; i < joinCount && len(joined) < workspaces; i++ {
+			workspaceID := rng.Intn(workspaces) + 1
+			if joined[workspaceID] {
+				continue
+			}
+			joined[workspaceID] = true
+			rows = append(rows, models.WorkspaceUser{WorkspaceID: workspaceID, UserID: userID})
+		}
+	}
+	if err := models.DB.CreateInBatches(rows, 500).Error; err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// seedPapers generates count fake papers owned by random users, with a
+// randomized visibility option and (for "workspace") a random workspace.
+func seedPapers(rng *rand.Rand, users, workspaces, count int) (int, error) {
+	rows := make([]models.Paper, 0, count)
+	for i := 0; i < count; i++ {
+		option := visibilityOptions[rng.Intn(len(visibilityOptions))]
+
+		paper := models.Paper{
+			Title:           gofakeit.Sentence(6),
+			Authors:         fmt.Sprintf("%s, %s", gofakeit.Name(), gofakeit.Name()),
+			Abstract:        gofakeit.Paragraph(1, 4, 12, " "),
+			CoverImage:      gofakeit.ImageURL(800, 600),
+			PublishedYear:   gofakeit.Year(),
+			Field:           gofakeit.RandomString([]string{"Computer Science", "Biology", "Physics", "Economics", "Mathematics"}),
+			Classifications: models.StringSlice(randomClassifications(rng, rng.Intn(3)+1)),
+			DOI:             gofakeit.UUID(),
+			Journal:         gofakeit.Company() + " Journal",
+			UserID:          rng.Intn(users) + 1,
+			IsPublic:        option != "",
+			PublicOption:    option,
+		}
+		if option == "workspace" {
+			paper.WorkspaceSiteID = rng.Intn(workspaces) + 1
+		}
+
+		rows = append(rows, paper)
+	}
+	if err := models.DB.CreateInBatches(rows, 500).Error; err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
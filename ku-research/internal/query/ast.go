@@ -0,0 +1,36 @@
+package query
+
+// Node is any node in a parsed query's AST.
+type Node interface {
+	node()
+}
+
+// Ident references a field on the record being evaluated, e.g. `field` or
+// `publishedYear`.
+type Ident struct {
+	Name string
+}
+
+// Literal is a string, number, or bool constant.
+type Literal struct {
+	Value interface{}
+}
+
+// Unary is `!operand`.
+type Unary struct {
+	Op      string
+	Operand Node
+}
+
+// Binary is `left op right` for comparisons (`==`, `!=`, `<`, ...), `in`,
+// and the boolean combinators `&&`/`||`.
+type Binary struct {
+	Op    string
+	Left  Node
+	Right Node
+}
+
+func (Ident) node()   {}
+func (Literal) node() {}
+func (Unary) node()   {}
+func (Binary) node()  {}
@@ -0,0 +1,199 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Evaluate runs node against record, which must be a struct (or pointer to
+// one); identifiers are resolved against its exported fields by JSON tag
+// or field name, case-insensitively. A nil node matches everything.
+func Evaluate(node Node, record interface{}) (bool, error) {
+	if node == nil {
+		return true, nil
+	}
+
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	result, err := eval(node, v)
+	if err != nil {
+		return false, err
+	}
+	b, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("query: expression did not evaluate to a bool")
+	}
+	return b, nil
+}
+
+func eval(node Node, v reflect.Value) (interface{}, error) {
+	switch n := node.(type) {
+	case Literal:
+		return n.Value, nil
+	case Ident:
+		return fieldValue(v, n.Name)
+	case Unary:
+		operand, err := eval(n.Operand, v)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("query: '!' requires a bool operand")
+		}
+		return !b, nil
+	case Binary:
+		return evalBinary(n, v)
+	default:
+		return nil, fmt.Errorf("query: unknown node type %T", node)
+	}
+}
+
+func evalBinary(n Binary, v reflect.Value) (interface{}, error) {
+	if n.Op == "&&" || n.Op == "||" {
+		left, err := eval(n.Left, v)
+		if err != nil {
+			return nil, err
+		}
+		leftBool, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("query: '%s' requires bool operands", n.Op)
+		}
+		if n.Op == "&&" && !leftBool {
+			return false, nil
+		}
+		if n.Op == "||" && leftBool {
+			return true, nil
+		}
+		right, err := eval(n.Right, v)
+		if err != nil {
+			return nil, err
+		}
+		rightBool, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("query: '%s' requires bool operands", n.Op)
+		}
+		return rightBool, nil
+	}
+
+	left, err := eval(n.Left, v)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.Right, v)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Op == "in" {
+		return membership(left, right)
+	}
+	return compare(n.Op, left, right)
+}
+
+// fieldValue resolves name against v's exported fields by JSON tag (before
+// any comma option) or field name, case-insensitively. Anonymous embedded
+// fields (e.g. gorm.Model) are searched too, so promoted fields like "id"
+// resolve the same way they would through JSON marshaling.
+func fieldValue(v reflect.Value, name string) (interface{}, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			if val, err := fieldValue(v.Field(i), name); err == nil {
+				return val, nil
+			}
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if strings.EqualFold(tag, name) || strings.EqualFold(f.Name, name) {
+			return v.Field(i).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("query: unknown field %q", name)
+}
+
+// membership implements `item in collection`, where collection is a slice
+// or array of comparable elements (used for Classifications, a []string).
+func membership(item, collection interface{}) (interface{}, error) {
+	cv := reflect.ValueOf(collection)
+	if cv.Kind() != reflect.Slice && cv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("query: 'in' requires a list on the right-hand side")
+	}
+	for i := 0; i < cv.Len(); i++ {
+		eq, err := equal(item, cv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if eq {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		eq, err := equal(left, right)
+		return !eq, err
+	case "<", "<=", ">", ">=":
+		l, lok := toFloat(left)
+		r, rok := toFloat(right)
+		if !lok || !rok {
+			return nil, fmt.Errorf("query: '%s' requires numeric operands", op)
+		}
+		switch op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		default:
+			return l >= r, nil
+		}
+	default:
+		return nil, fmt.Errorf("query: unknown operator %q", op)
+	}
+}
+
+func equal(left, right interface{}) (bool, error) {
+	if lf, lok := toFloat(left); lok {
+		if rf, rok := toFloat(right); rok {
+			return lf == rf, nil
+		}
+	}
+	return fmt.Sprint(left) == fmt.Sprint(right), nil
+}
+
+// lessThan orders two field values the same way compare's "<" does,
+// falling back to a string comparison for non-numeric values.
+func lessThan(a, b interface{}) (bool, error) {
+	if af, aok := toFloat(a); aok {
+		if bf, bok := toFloat(b); bok {
+			return af < bf, nil
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b), nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,62 @@
+package query
+
+import "testing"
+
+type evalTestRecord struct {
+	Field           string   `json:"field"`
+	PublishedYear   int      `json:"publishedYear"`
+	Classifications []string `json:"classifications"`
+	IsPublic        bool     `json:"isPublic"`
+}
+
+func TestEvaluate(t *testing.T) {
+	record := evalTestRecord{
+		Field:           "Computer Science",
+		PublishedYear:   2022,
+		Classifications: []string{"Machine Learning", "Robotics"},
+		IsPublic:        true,
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"field == 'Computer Science'", true},
+		{"field == 'Physics'", false},
+		{"field != 'Physics'", true},
+		{"publishedYear >= 2020 && publishedYear <= 2024", true},
+		{"publishedYear > 2022", false},
+		{"field == 'Physics' || isPublic == true", true},
+		{"!(field == 'Physics')", true},
+		{"'Robotics' in classifications", true},
+		{"'Genomics' in classifications", false},
+	}
+
+	for _, tc := range cases {
+		node, err := Parse(tc.expr, DefaultLimits)
+		if err != nil {
+			t.Errorf("Parse(%q) returned unexpected error: %v", tc.expr, err)
+			continue
+		}
+		got, err := Evaluate(node, record)
+		if err != nil {
+			t.Errorf("Evaluate(%q) returned unexpected error: %v", tc.expr, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Evaluate(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestEvaluateUnknownField(t *testing.T) {
+	record := evalTestRecord{Field: "Physics"}
+	node, err := Parse("doesNotExist == 'x'", DefaultLimits)
+	if err != nil {
+		t.Fatalf("Parse returned unexpected error: %v", err)
+	}
+	if _, err := Evaluate(node, record); err == nil {
+		t.Error("Evaluate with an unknown field should have returned an error")
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokBool
+	tokAnd
+	tokOr
+	tokNot
+	tokIn
+	tokEq
+	tokNeq
+	tokLt
+	tokLte
+	tokGt
+	tokGte
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lexer turns a query string into a stream of tokens for the parser.
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: []rune(input)}
+}
+
+func (l *lexer) peekRune() (rune, bool) {
+	if l.pos >= len(l.input) {
+		return 0, false
+	}
+	return l.input[l.pos], true
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+
+	r, ok := l.peekRune()
+	if !ok {
+		return token{kind: tokEOF}, nil
+	}
+
+	switch {
+	case r == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "("}, nil
+	case r == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")"}, nil
+	case r == '\'' || r == '"':
+		return l.lexString(r)
+	case r == '!':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.pos++
+			return token{kind: tokNeq, text: "!="}, nil
+		}
+		return token{kind: tokNot, text: "!"}, nil
+	case r == '=':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.pos++
+			return token{kind: tokEq, text: "=="}, nil
+		}
+		return token{}, fmt.Errorf("query: unexpected '=' (did you mean '=='?)")
+	case r == '<':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.pos++
+			return token{kind: tokLte, text: "<="}, nil
+		}
+		return token{kind: tokLt, text: "<"}, nil
+	case r == '>':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '=' {
+			l.pos++
+			return token{kind: tokGte, text: ">="}, nil
+		}
+		return token{kind: tokGt, text: ">"}, nil
+	case r == '&':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '&' {
+			l.pos++
+			return token{kind: tokAnd, text: "&&"}, nil
+		}
+		return token{}, fmt.Errorf("query: unexpected '&' (did you mean '&&'?)")
+	case r == '|':
+		l.pos++
+		if next, ok := l.peekRune(); ok && next == '|' {
+			l.pos++
+			return token{kind: tokOr, text: "||"}, nil
+		}
+		return token{}, fmt.Errorf("query: unexpected '|' (did you mean '||'?)")
+	case unicode.IsDigit(r) || r == '-':
+		return l.lexNumber()
+	case unicode.IsLetter(r) || r == '_':
+		return l.lexIdent()
+	default:
+		return token{}, fmt.Errorf("query: unexpected character %q", r)
+	}
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+}
+
+func (l *lexer) lexString(quote rune) (token, error) {
+	l.pos++ // consume opening quote
+	start := l.pos
+	for l.pos < len(l.input) && l.input[l.pos] != quote {
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return token{}, fmt.Errorf("query: unterminated string literal")
+	}
+	text := string(l.input[start:l.pos])
+	l.pos++ // consume closing quote
+	return token{kind: tokString, text: text}, nil
+}
+
+func (l *lexer) lexNumber() (token, error) {
+	start := l.pos
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.input) && (unicode.IsDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+		l.pos++
+	}
+	text := string(l.input[start:l.pos])
+	if text == "" || text == "-" {
+		return token{}, fmt.Errorf("query: invalid number literal")
+	}
+	return token{kind: tokNumber, text: text}, nil
+}
+
+func (l *lexer) lexIdent() (token, error) {
+	start := l.pos
+	for l.pos < len(l.input) && (unicode.IsLetter(l.input[l.pos]) || unicode.IsDigit(l.input[l.pos]) || l.input[l.pos] == '_') {
+		l.pos++
+	}
+	text := string(l.input[start:l.pos])
+	switch strings.ToLower(text) {
+	case "in":
+		return token{kind: tokIn, text: text}, nil
+	case "true", "false":
+		return token{kind: tokBool, text: text}, nil
+	default:
+		return token{kind: tokIdent, text: text}, nil
+	}
+}
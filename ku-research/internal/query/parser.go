@@ -0,0 +1,243 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Limits bounds how large a parsed AST may grow, so a hostile or buggy
+// client can't make evaluation arbitrarily expensive.
+type Limits struct {
+	MaxNodes int
+	MaxDepth int
+}
+
+// DefaultLimits is applied by Parse when the caller doesn't supply its own.
+var DefaultLimits = Limits{MaxNodes: 200, MaxDepth: 20}
+
+// Parse lexes and parses a query expression into an AST, enforcing limits
+// on the way. An empty expression parses to a nil Node, which Evaluate
+// treats as "matches everything".
+func Parse(expr string, limits Limits) (Node, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	p := &parser{lex: newLexer(expr), limits: limits}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	node, err := p.parseOr(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("query: unexpected trailing token %q", p.tok.text)
+	}
+	return node, nil
+}
+
+type parser struct {
+	lex    *lexer
+	tok    token
+	limits Limits
+	nodes  int
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// countNode tracks the total number of AST nodes constructed, independent
+// of depth: MaxNodes bounds evaluation cost, not nesting.
+func (p *parser) countNode() error {
+	p.nodes++
+	if p.nodes > p.limits.MaxNodes {
+		return fmt.Errorf("query: expression exceeds the maximum of %d nodes", p.limits.MaxNodes)
+	}
+	return nil
+}
+
+// checkDepth enforces MaxDepth. Callers pass the depth only constructs that
+// actually nest (parens, stacked `!`) bump it to — not every precedence
+// level in the || -> && -> ! -> comparison -> primary grammar chain, which
+// would exhaust it after a handful of parenthesized groups.
+func (p *parser) checkDepth(depth int) error {
+	if depth > p.limits.MaxDepth {
+		return fmt.Errorf("query: expression exceeds the maximum depth of %d", p.limits.MaxDepth)
+	}
+	return nil
+}
+
+// parseOr handles `||`, the lowest-precedence operator.
+func (p *parser) parseOr(depth int) (Node, error) {
+	if err := p.countNode(); err != nil {
+		return nil, err
+	}
+
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "||", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd handles `&&`.
+func (p *parser) parseAnd(depth int) (Node, error) {
+	if err := p.countNode(); err != nil {
+		return nil, err
+	}
+
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "&&", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseUnary handles `!`.
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if err := p.countNode(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokNot {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.checkDepth(depth + 1); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "!", Operand: operand}, nil
+	}
+	return p.parseComparison(depth)
+}
+
+// parseComparison handles `==`, `!=`, `<`, `<=`, `>`, `>=`, and `in`.
+func (p *parser) parseComparison(depth int) (Node, error) {
+	if err := p.countNode(); err != nil {
+		return nil, err
+	}
+
+	left, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	op := ""
+	switch p.tok.kind {
+	case tokEq:
+		op = "=="
+	case tokNeq:
+		op = "!="
+	case tokLt:
+		op = "<"
+	case tokLte:
+		op = "<="
+	case tokGt:
+		op = ">"
+	case tokGte:
+		op = ">="
+	case tokIn:
+		op = "in"
+	default:
+		return left, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	right, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	return Binary{Op: op, Left: left, Right: right}, nil
+}
+
+// parsePrimary handles literals, identifiers, lists, and parenthesized
+// sub-expressions.
+func (p *parser) parsePrimary(depth int) (Node, error) {
+	if err := p.countNode(); err != nil {
+		return nil, err
+	}
+
+	switch p.tok.kind {
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.checkDepth(depth + 1); err != nil {
+			return nil, err
+		}
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, fmt.Errorf("query: expected ')'")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case tokIdent:
+		name := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return Ident{Name: name}, nil
+	case tokString:
+		val := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return Literal{Value: val}, nil
+	case tokNumber:
+		val, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("query: invalid number %q", p.tok.text)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return Literal{Value: val}, nil
+	case tokBool:
+		val := p.tok.text == "true"
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return Literal{Value: val}, nil
+	default:
+		return nil, fmt.Errorf("query: unexpected token %q", p.tok.text)
+	}
+}
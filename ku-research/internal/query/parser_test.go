@@ -0,0 +1,64 @@
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseValidExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"field == 'Computer Science'",
+		"publishedYear >= 2020 && publishedYear <= 2024",
+		"field == 'Physics' || field == 'Biology'",
+		"!(field == 'Physics')",
+		"'Robotics' in classifications",
+		"(((((publishedYear > 2000)))))",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr, DefaultLimits); err != nil {
+			t.Errorf("Parse(%q) returned unexpected error: %v", expr, err)
+		}
+	}
+}
+
+// TestParseDeeplyNestedParens guards against the depth counter being bumped
+// on every precedence-level recursion instead of on real nesting: that bug
+// made DefaultLimits.MaxDepth (20) trip after only a handful of paren
+// groups, well under any genuinely deep expression.
+func TestParseDeeplyNestedParens(t *testing.T) {
+	expr := strings.Repeat("(", 15) + "publishedYear > 2000" + strings.Repeat(")", 15)
+	if _, err := Parse(expr, DefaultLimits); err != nil {
+		t.Errorf("Parse(%d nested parens) returned unexpected error: %v", 15, err)
+	}
+}
+
+func TestParseDepthLimitStillEnforced(t *testing.T) {
+	limits := Limits{MaxNodes: 1000, MaxDepth: 3}
+	expr := strings.Repeat("(", 5) + "publishedYear > 2000" + strings.Repeat(")", 5)
+	if _, err := Parse(expr, limits); err == nil {
+		t.Errorf("Parse(%q) with MaxDepth=3 should have failed", expr)
+	}
+}
+
+func TestParseNodeLimitEnforced(t *testing.T) {
+	limits := Limits{MaxNodes: 2, MaxDepth: 20}
+	if _, err := Parse("field == 'a' && field == 'b'", limits); err == nil {
+		t.Error("expected an error once MaxNodes is exceeded")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"field ==",
+		"(field == 'a'",
+		"field = 'a'",
+		"field & field",
+		"'unterminated",
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr, DefaultLimits); err == nil {
+			t.Errorf("Parse(%q) should have returned an error", expr)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package query implements the small filter DSL /get-research accepts:
+// `field == 'Computer Science' && publishedYear >= 2020`. A lexer turns
+// the expression into tokens, a recursive-descent parser builds an AST
+// bounded by Limits, and Evaluate walks that AST against a struct via
+// reflection.
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"ku-research/models"
+)
+
+// Request is the structured filter /get-research accepts on top of the
+// caller's identity: a query-DSL expression, a sort field, pagination, and
+// a field projection.
+type Request struct {
+	Query  string   `json:"query"`
+	Sort   string   `json:"sort"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+	Fields []string `json:"fields"`
+}
+
+// Result is what /get-research returns once a Request has been applied.
+type Result struct {
+	Papers     []interface{} `json:"papers"`
+	Total      int           `json:"total"`
+	NextOffset int           `json:"nextOffset"`
+}
+
+// Apply filters papers by req.Query, sorts, paginates, and then projects
+// fields, in that order. Callers must run access-control filtering first;
+// Apply only ever narrows what it's given, it never widens it.
+func Apply(papers []models.Paper, req Request) (*Result, error) {
+	node, err := Parse(req.Query, DefaultLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]models.Paper, 0, len(papers))
+	for _, paper := range papers {
+		ok, err := Evaluate(node, paper)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, paper)
+		}
+	}
+
+	if req.Sort != "" {
+		if err := sortPapers(matched, req.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	total := len(matched)
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	limit := req.Limit
+	if limit <= 0 || offset+limit > total {
+		limit = total - offset
+	}
+	page := matched[offset : offset+limit]
+
+	nextOffset := offset + limit
+	if nextOffset >= total {
+		nextOffset = -1
+	}
+
+	projected := make([]interface{}, len(page))
+	for i, paper := range page {
+		if len(req.Fields) == 0 {
+			projected[i] = paper
+			continue
+		}
+		p, err := project(paper, req.Fields)
+		if err != nil {
+			return nil, err
+		}
+		projected[i] = p
+	}
+
+	return &Result{Papers: projected, Total: total, NextOffset: nextOffset}, nil
+}
+
+func sortPapers(papers []models.Paper, field string) error {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var sortErr error
+	sort.SliceStable(papers, func(i, j int) bool {
+		a, err := fieldValue(reflect.ValueOf(papers[i]), field)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		b, err := fieldValue(reflect.ValueOf(papers[j]), field)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		less, err := lessThan(a, b)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		if desc {
+			return !less
+		}
+		return less
+	})
+	return sortErr
+}
+
+// project builds a map containing only the requested fields, keyed by
+// their JSON tag (or field name, if untagged), so clients can ask for a
+// thinner payload than the full Paper.
+func project(paper models.Paper, fields []string) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		val, err := fieldValue(reflect.ValueOf(paper), field)
+		if err != nil {
+			return nil, fmt.Errorf("query: cannot project field %q: %w", field, err)
+		}
+		out[field] = val
+	}
+	return out, nil
+}
@@ -1,52 +1,32 @@
 package main
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
+	"ku-research/internal/query"
+	"ku-research/middleware"
+	"ku-research/models"
 	"ku-research/sdk"
 	"log"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// ResearchPaper represents a research paper with visibility settings
-type ResearchPaper struct {
-	ID              string   `json:"id"`
-	Title           string   `json:"title"`
-	Authors         string   `json:"authors"`
-	Abstract        string   `json:"abstract"`
-	CoverImage      string   `json:"coverImage"`
-	PublishedYear   int      `json:"publishedYear"`
-	Field           string   `json:"field"`
-	Classifications []string `json:"classifications"`
-	DOI             string   `json:"doi,omitempty"`
-	Journal         string   `json:"journal,omitempty"`
-
-	// Visibility fields
-	UserID          int    `json:"userId"`
-	IsPublic        bool   `json:"isPublic"`
-	PublicOption    string `json:"publicOption,omitempty"` // "workspace", "site", or "everyone"
-	WorkspaceSiteID int    `json:"workspaceSiteID,omitempty"`
-}
-
-type WorkspaceUser struct {
-	WorkspaceID int `json:"workspaceId"`
-	UserID      int `json:"userId"`
-}
-
-var (
-	papers         []ResearchPaper
-	workspaceUsers []WorkspaceUser
-	siteUsers      []int // User IDs that belong to site #1
-	mu             sync.Mutex
-)
+// sdkSigningKey is the secret shared with the Super App SDK; share tokens
+// are signed with it too so both trust the same key material.
+const sdkSigningKey = "super-secret-key"
 
 func main() {
-	// Initialize with sample data
-	papers = getSamplePapers()
-	workspaceUsers = getSampleWorkspaceUsers()
-	siteUsers = getSampleSiteUsers()
+	if err := models.Init(); err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v\n", err)
+	}
+
+	superApp := sdk.NewSuperAppSDK(sdkSigningKey)
+	refreshPapersTotal()
 
 	app := fiber.New(fiber.Config{
 		BodyLimit: 10 * 1024 * 1024,
@@ -62,43 +42,172 @@ func main() {
 		return c.Next()
 	})
 
+	app.Use(middleware.Metrics())
+
 	app.Use(func(c *fiber.Ctx) error {
 		log.Printf("📥 Incoming request to: %s %s\n", c.Method(), c.Path())
 		log.Printf("📄 Request body: %s\n", string(c.Body()))
 		return c.Next()
 	})
 
-	// Get research papers with access control
-	app.Post("/get-research", func(c *fiber.Ctx) error {
-		// Parse request body to get the requesting user's ID
-		var request struct {
-			UserID int `json:"userId"`
+	app.Use(middleware.ShareAuth(sdkSigningKey))
+
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	// Exchange Super App credentials for an access + refresh token pair
+	app.Post("/auth/login", func(c *fiber.Ctx) error {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request",
+			})
 		}
 
-		if err := c.BodyParser(&request); err != nil {
-			log.Printf("❌ Error parsing request: %v\n", err)
+		userID, workspaces, site, err := superApp.VerifyCredentials(body.Username, body.Password)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid credentials",
+			})
+		}
+
+		accessToken, refreshToken, err := middleware.IssueTokenPair(userID, workspaces, site, sdkSigningKey)
+		if err != nil {
+			log.Printf("❌ Error issuing tokens: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to issue tokens",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"accessToken":  accessToken,
+			"refreshToken": refreshToken,
+		})
+	})
+
+	// Mint a new access token from a still-valid refresh token
+	app.Post("/auth/refresh", func(c *fiber.Ctx) error {
+		var body struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		if err := c.BodyParser(&body); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid request",
 			})
 		}
 
-		userID := request.UserID
-		log.Printf("🔍 Retrieving papers for user ID: %d\n", userID)
+		claims, err := middleware.ParseAuthToken(body.RefreshToken, sdkSigningKey, middleware.TokenTypeRefresh)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid, expired, or revoked refresh token",
+			})
+		}
 
-		mu.Lock()
-		// Filter papers based on access permissions
-		accessiblePapers := filterAccessiblePapers(papers, userID)
-		mu.Unlock()
+		accessToken, err := middleware.IssueAccessToken(claims.UserID(), claims.Workspaces, claims.Site, claims.ID, sdkSigningKey)
+		if err != nil {
+			log.Printf("❌ Error issuing access token: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to issue access token",
+			})
+		}
 
-		log.Printf("📤 Sending %d accessible papers\n", len(accessiblePapers))
 		return c.JSON(fiber.Map{
-			"papers": accessiblePapers,
+			"accessToken": accessToken,
 		})
 	})
 
+	// Revoke the caller's current access token, along with the refresh
+	// token it was paired with at login, so logout can't be undone by
+	// calling /auth/refresh with the token still in hand.
+	app.Post("/auth/logout", middleware.AuthRequired(sdkSigningKey), func(c *fiber.Ctx) error {
+		jti := c.Locals("jti").(string)
+		pairJTI := c.Locals("pairJTI").(string)
+		expiresAt := c.Locals("tokenExpiresAt").(time.Time)
+
+		if err := models.RevokeToken(jti, expiresAt); err != nil {
+			log.Printf("❌ Error revoking token: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to revoke token",
+			})
+		}
+		if pairJTI != "" {
+			// The refresh token's own expiry isn't known here; revoking it
+			// through at least RefreshTokenTTL covers its real lifetime.
+			if err := models.RevokeToken(pairJTI, time.Now().Add(middleware.RefreshTokenTTL)); err != nil {
+				log.Printf("❌ Error revoking paired token: %v\n", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to revoke token",
+				})
+			}
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Logged out successfully",
+		})
+	})
+
+	// Get research papers with access control. A valid bearer token grants
+	// the caller's own accessible papers; a redeemed share token (see
+	// middleware.ShareAuth) grants its one paper on top of that, or on its
+	// own if the caller isn't logged in at all.
+	app.Post("/get-research", middleware.OptionalAuth(sdkSigningKey), func(c *fiber.Ctx) error {
+		userID, loggedIn := c.Locals("userID").(int)
+		sharedPaperID, shared := c.Locals("sharedPaperID").(models.PaperID)
+		if !loggedIn && !shared {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Missing bearer token",
+			})
+		}
+
+		var req query.Request
+		if err := c.BodyParser(&req); err != nil {
+			log.Printf("❌ Error parsing request: %v\n", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request",
+			})
+		}
+
+		var accessiblePapers []models.Paper
+		if loggedIn {
+			log.Printf("🔍 Retrieving papers for user ID: %d\n", userID)
+			papers, err := models.GetAccessiblePapers(userID)
+			if err != nil {
+				log.Printf("❌ Error fetching papers: %v\n", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to fetch papers",
+				})
+			}
+			accessiblePapers = papers
+		}
+
+		if shared {
+			if sharedPaper, err := models.GetPaper(sharedPaperID); err == nil {
+				accessiblePapers = append(accessiblePapers, *sharedPaper)
+			}
+		}
+
+		// The query DSL only ever narrows the set of papers access control
+		// already decided the caller may see.
+		result, err := query.Apply(accessiblePapers, req)
+		if err != nil {
+			log.Printf("❌ Error applying query: %v\n", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		middleware.AccessiblePapersReturned.Observe(float64(len(result.Papers)))
+		logAudit("get-research", userID, len(accessiblePapers), len(result.Papers))
+
+		log.Printf("📤 Sending %d of %d accessible papers\n", len(result.Papers), result.Total)
+		return c.JSON(result)
+	})
+
 	// Add new paper endpoint
-	app.Post("/add-paper", func(c *fiber.Ctx) error {
-		var newPaper ResearchPaper
+	app.Post("/add-paper", middleware.AuthRequired(sdkSigningKey), func(c *fiber.Ctx) error {
+		var newPaper models.Paper
 		if err := c.BodyParser(&newPaper); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Invalid paper data",
@@ -112,15 +221,17 @@ func main() {
 			})
 		}
 
-		// Generate ID if not provided
-		if newPaper.ID == "" {
-			newPaper.ID = generateID()
-		}
+		// The paper is always attributed to the authenticated caller, not
+		// whatever userId the request body claims.
+		newPaper.UserID = c.Locals("userID").(int)
 
-		// Add paper to the database
-		mu.Lock()
-		papers = append(papers, newPaper)
-		mu.Unlock()
+		if err := models.CreatePaper(&newPaper); err != nil {
+			log.Printf("❌ Error saving paper: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to save paper",
+			})
+		}
+		refreshPapersTotal()
 
 		log.Printf("📤 Paper added: %v\n", newPaper)
 		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -129,6 +240,101 @@ func main() {
 		})
 	})
 
+	// Create a shareable link + secret for a paper the caller owns
+	app.Post("/papers/:id/share", middleware.AuthRequired(sdkSigningKey), func(c *fiber.Ctx) error {
+		var body struct {
+			TTLSeconds int `json:"ttlSeconds"`
+			MaxUses    int `json:"maxUses"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request",
+			})
+		}
+
+		userID := c.Locals("userID").(int)
+
+		paperID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid paper id",
+			})
+		}
+
+		paper, err := models.GetPaper(models.PaperID(paperID))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Paper not found",
+			})
+		}
+		if paper.UserID != userID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Only the owner can share this paper",
+			})
+		}
+
+		ttl := time.Duration(body.TTLSeconds) * time.Second
+		if ttl <= 0 {
+			ttl = 7 * 24 * time.Hour
+		}
+
+		share, secret, err := models.NewShare(paper.ID, userID, ttl, body.MaxUses)
+		if err != nil {
+			log.Printf("❌ Error creating share: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create share",
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"link":      "/shares/" + share.Token,
+			"secret":    secret,
+			"expiresAt": share.ExpiresAt,
+		})
+	})
+
+	// Redeem a share's secret for a short-lived access token
+	app.Post("/shares/:token/redeem", func(c *fiber.Ctx) error {
+		var body struct {
+			Secret string `json:"secret"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request",
+			})
+		}
+
+		share, err := models.GetShareByToken(c.Params("token"))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Share not found or expired",
+			})
+		}
+
+		if err := share.Redeem(body.Secret); err != nil {
+			if errors.Is(err, models.ErrShareExhausted) {
+				return c.Status(fiber.StatusGone).JSON(fiber.Map{
+					"error": "Share has no uses remaining",
+				})
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid secret",
+			})
+		}
+
+		accessToken, err := middleware.IssueShareToken(share.PaperID, sdkSigningKey)
+		if err != nil {
+			log.Printf("❌ Error issuing share token: %v\n", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to issue access token",
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"accessToken": accessToken,
+		})
+	})
+
 	go func() {
 		log.Println("📦 Ku Research running at http://localhost:8083")
 		log.Println("✅ Ready to accept connections")
@@ -138,21 +344,22 @@ func main() {
 	}()
 
 	time.Sleep(1 * time.Second)
-	sdk := sdk.NewSuperAppSDK("super-secret-key")
 	maxRetries := 5
 
 	for i := range maxRetries {
 		log.Printf("Attempting to register with Super App (attempt %d/%d)\n", i+1, maxRetries)
-		err := sdk.Register("Ku Research", []string{
+		err := superApp.Register("Ku Research", []string{
 			"get-research",
 			"add-paper",
 		},
 			"http://host.docker.internal:8083",
 		)
 		if err == nil {
+			middleware.SDKRegisterAttemptsTotal.WithLabelValues("success").Inc()
 			log.Println("✅ Ku Research registered successfully")
 			break
 		}
+		middleware.SDKRegisterAttemptsTotal.WithLabelValues("failure").Inc()
 		log.Printf("❌ Registration attempt %d failed: %v\n", i+1, err)
 		if i < maxRetries-1 {
 			log.Println("Waiting before retry...")
@@ -165,96 +372,39 @@ func main() {
 	select {}
 }
 
-func filterAccessiblePapers(allPapers []ResearchPaper, userID int) []ResearchPaper {
-	var accessiblePapers []ResearchPaper
-
-	for _, paper := range allPapers {
-		if hasAccess(paper, userID) {
-			accessiblePapers = append(accessiblePapers, paper)
-		}
+// refreshPapersTotal re-reads the paper count from the database into the
+// mini_server_papers_total gauge. It's cheap enough to call after every
+// write; a periodic ticker isn't worth the complexity at this scale.
+func refreshPapersTotal() {
+	var count int64
+	if err := models.DB.Model(&models.Paper{}).Count(&count).Error; err != nil {
+		log.Printf("❌ Error counting papers for metrics: %v\n", err)
+		return
 	}
-
-	return accessiblePapers
+	middleware.PapersTotal.Set(float64(count))
 }
 
-func hasAccess(paper ResearchPaper, userID int) bool {
-	if paper.UserID == userID {
-		return true
-	}
-
-	if !paper.IsPublic {
-		return false
-	}
-
-	if paper.PublicOption == "everyone" {
-		return true
-	}
-
-	if paper.PublicOption == "site" {
-		for _, siteUserID := range siteUsers {
-			if siteUserID == userID {
-				return true
-			}
-		}
-		return false
-	}
-
-	if paper.PublicOption == "workspace" {
-		for _, workspaceUser := range workspaceUsers {
-			if workspaceUser.WorkspaceID == paper.WorkspaceSiteID && workspaceUser.UserID == userID {
-				return true
-			}
-		}
-		return false
-	}
-
-	return false
-}
-
-// generateID generates a simple ID for new papers
-func generateID() string {
-	mu.Lock()
-	defer mu.Unlock()
-	return fmt.Sprintf("%d", len(papers)+1)
+// auditEntry is one structured log line per access-control decision, so
+// operators can reconstruct after the fact why a user saw the papers they
+// did.
+type auditEntry struct {
+	Event            string `json:"event"`
+	UserID           int    `json:"userId"`
+	PapersConsidered int    `json:"papersConsidered"`
+	PapersReturned   int    `json:"papersReturned"`
 }
 
-func getSamplePapers() []ResearchPaper {
-	return []ResearchPaper{
-		{
-			ID:            "1",
-			Title:         "Quantum Computing: Recent Advances and Future Directions",
-			Authors:       "Dr. Richard Feynman, Dr. Lisa Chen",
-			Abstract:      "This paper reviews recent developments in quantum computing, focusing on quantum supremacy experiments and potential applications in cryptography, optimization, and simulation of quantum systems.",
-			CoverImage:    "https://images.unsplash.com/photo-1635070041078-e363dbe005cb?ixlib=rb-4.0.3&ixid=M3wxMjA3fDB8MHxwaG90by1wYWdlfHx8fGVufDB8fHx8fA%3D%3D&auto=format&fit=crop&w=2070&q=80",
-			PublishedYear: 2023,
-			Field:         "Computer Science",
-			Classifications: []string{
-				"Quantum Computing",
-				"Theoretical Physics",
-				"Cryptography",
-			},
-			DOI:          "10.1038/s41586-019-1666-5",
-			Journal:      "Nature Quantum Information",
-			UserID:       1,
-			IsPublic:     true,
-			PublicOption: "everyone",
-		},
+func logAudit(event string, userID, papersConsidered, papersReturned int) {
+	entry := auditEntry{
+		Event:            event,
+		UserID:           userID,
+		PapersConsidered: papersConsidered,
+		PapersReturned:   papersReturned,
 	}
-}
-
-func getSampleWorkspaceUsers() []WorkspaceUser {
-	return []WorkspaceUser{
-		{WorkspaceID: 1, UserID: 2},
-		{WorkspaceID: 3, UserID: 2},
-		{WorkspaceID: 1, UserID: 3},
-		{WorkspaceID: 3, UserID: 3},
-		{WorkspaceID: 9, UserID: 3},
-		{WorkspaceID: 1, UserID: 4},
-		{WorkspaceID: 3, UserID: 4},
-		{WorkspaceID: 8, UserID: 4},
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("❌ Error marshaling audit entry: %v\n", err)
+		return
 	}
-}
-
-func getSampleSiteUsers() []int {
-	return []int{1, 2, 3, 4}
+	log.Printf("🧾 %s\n", line)
 }
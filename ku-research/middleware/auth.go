@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"ku-research/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessTokenTTL is how long a token issued by /auth/login or
+	// /auth/refresh is valid before it must be refreshed again.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a refresh token stays valid.
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// TokenType distinguishes an access token from the refresh token used only
+// to mint new ones, so a long-lived refresh token can't be replayed as a
+// Bearer access token and vice versa.
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+// AuthClaims is the JWT payload issued by /auth/login. The standard "sub"
+// claim carries the user ID as a string; Workspaces and Site let handlers
+// make access decisions without a round-trip to the Super App. PairJTI
+// names the jti of the other token minted alongside this one (access <->
+// refresh), so revoking one on logout can revoke both.
+type AuthClaims struct {
+	Workspaces []int     `json:"workspaces"`
+	Site       int       `json:"site"`
+	Type       TokenType `json:"typ"`
+	PairJTI    string    `json:"pairJti,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID parses the standard "sub" claim back into the int ID it was
+// issued with.
+func (c AuthClaims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+// IssueTokenPair mints the access + refresh tokens returned by /auth/login.
+// The two are cross-linked via PairJTI so that revoking either one on
+// logout can also revoke the other.
+func IssueTokenPair(userID int, workspaces []int, site int, signingKey string) (accessToken, refreshToken string, err error) {
+	accessJTI, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	refreshJTI, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, err = signToken(userID, workspaces, site, AccessTokenTTL, TokenTypeAccess, accessJTI, refreshJTI, signingKey)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = signToken(userID, workspaces, site, RefreshTokenTTL, TokenTypeRefresh, refreshJTI, accessJTI, signingKey)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// IssueAccessToken signs a short-lived access token for userID on behalf of
+// /auth/refresh. pairJTI should be the refresh token's own jti, so the new
+// access token stays linked to the refresh token that minted it.
+func IssueAccessToken(userID int, workspaces []int, site int, pairJTI, signingKey string) (string, error) {
+	jti, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	return signToken(userID, workspaces, site, AccessTokenTTL, TokenTypeAccess, jti, pairJTI, signingKey)
+}
+
+func signToken(userID int, workspaces []int, site int, ttl time.Duration, typ TokenType, jti, pairJTI, signingKey string) (string, error) {
+	claims := AuthClaims{
+		Workspaces: workspaces,
+		Site:       site,
+		Type:       typ,
+		PairJTI:    pairJTI,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
+}
+
+// ParseAuthToken validates an AuthClaims token's signature, expiry, and
+// type, and rejects it if its jti has been revoked. wantType lets callers
+// enforce that a Bearer header carries an access token and a refresh
+// request carries a refresh token, so neither can be used in place of the
+// other.
+func ParseAuthToken(token, signingKey string, wantType TokenType) (*AuthClaims, error) {
+	claims := &AuthClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(signingKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != wantType {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	revoked, err := models.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// AuthRequired parses `Authorization: Bearer <jwt>`, validates it with
+// ParseAuthToken as an access token, and stores the authenticated user's
+// ID, workspaces, and site under c.Locals so downstream handlers no longer
+// need to trust a userId in the body.
+func AuthRequired(signingKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Missing bearer token",
+			})
+		}
+
+		claims, err := ParseAuthToken(token, signingKey, TokenTypeAccess)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid, expired, or revoked token",
+			})
+		}
+
+		c.Locals("userID", claims.UserID())
+		c.Locals("workspaces", claims.Workspaces)
+		c.Locals("site", claims.Site)
+		c.Locals("jti", claims.ID)
+		c.Locals("pairJTI", claims.PairJTI)
+		c.Locals("tokenExpiresAt", claims.ExpiresAt.Time)
+		return c.Next()
+	}
+}
+
+// OptionalAuth behaves like AuthRequired but lets the request through when
+// no bearer token is present at all, so a route that also accepts share
+// tokens (see ShareAuth) can require "logged in OR valid share" in the
+// handler itself. A present-but-invalid token is still rejected.
+func OptionalAuth(signingKey string) fiber.Handler {
+	required := AuthRequired(signingKey)
+	return func(c *fiber.Ctx) error {
+		if !strings.HasPrefix(c.Get("Authorization"), "Bearer ") {
+			return c.Next()
+		}
+		return required(c)
+	}
+}
+
+// randomToken returns a 16-byte, URL-safe random string, used for JWT IDs.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mini_server_http_requests_total",
+		Help: "Total HTTP requests handled, labeled by method, path, and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mini_server_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, labeled by method and path.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mini_server_http_requests_in_flight",
+		Help: "Number of HTTP requests currently being handled.",
+	})
+
+	// PapersTotal should be set (not incremented) by callers whenever the
+	// paper count changes, since it tracks a count owned by the database.
+	PapersTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mini_server_papers_total",
+		Help: "Total number of papers currently stored.",
+	})
+
+	// AccessiblePapersReturned should be observed once per /get-research
+	// call with the number of papers sent back to the caller.
+	AccessiblePapersReturned = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mini_server_accessible_papers_returned",
+		Help:    "Number of papers returned per /get-research call.",
+		Buckets: []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000},
+	})
+
+	// SDKRegisterAttemptsTotal should be incremented once per Super App
+	// registration attempt with result set to "success" or "failure".
+	SDKRegisterAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mini_server_sdk_register_attempts_total",
+		Help: "Attempts to register with the Super App, labeled by outcome.",
+	}, []string{"result"})
+)
+
+// Metrics records request counts, latency, and in-flight concurrency for
+// every request that passes through it. Mount it before the existing
+// request-logging middleware so it wraps it (and everything after it).
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		start := time.Now()
+		err := c.Next()
+		duration := time.Since(start).Seconds()
+
+		path := c.Route().Path
+		// c.Method() is an unsafe zero-copy alias into fasthttp's reused
+		// request buffer; *Vec retains label values as map keys forever, so
+		// without cloning it the string mutates in place once the
+		// connection's buffer is overwritten by a later request.
+		method := strings.Clone(c.Method())
+		status := strconv.Itoa(c.Response().StatusCode())
+
+		httpRequestsTotal.WithLabelValues(method, path, status).Inc()
+		httpRequestDuration.WithLabelValues(method, path).Observe(duration)
+
+		return err
+	}
+}
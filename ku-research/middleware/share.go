@@ -0,0 +1,59 @@
+// Package middleware holds Fiber middleware shared across Ku Research's
+// handlers: token issuance/verification for shared links today, and the
+// site-wide JWT auth that supersedes it.
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"ku-research/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// shareTokenTTL is how long a token issued by redeeming a share stays valid.
+const shareTokenTTL = 15 * time.Minute
+
+// shareClaims is encoded into the JWT returned by POST /shares/:token/redeem.
+type shareClaims struct {
+	PaperID models.PaperID `json:"paperId"`
+	jwt.RegisteredClaims
+}
+
+// IssueShareToken signs a short-lived token granting read access to paperID.
+func IssueShareToken(paperID models.PaperID, signingKey string) (string, error) {
+	claims := shareClaims{
+		PaperID: paperID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(shareTokenTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
+}
+
+// ShareAuth recognizes an `Authorization: Share <jwt>` header and, when the
+// token is present and valid, stores the authorized paper ID under
+// c.Locals("sharedPaperID") so handlers can grant access to that one paper
+// regardless of its own IsPublic/PublicOption settings. Any other
+// Authorization scheme is left untouched for later middleware to handle.
+func ShareAuth(signingKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get("Authorization"), "Share ")
+		if !ok {
+			return c.Next()
+		}
+
+		claims := &shareClaims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte(signingKey), nil
+		})
+		if err != nil {
+			return c.Next()
+		}
+
+		c.Locals("sharedPaperID", claims.PaperID)
+		return c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// Migrate runs auto-migration for every model this package owns. It is
+// safe to call repeatedly; GORM only adds the columns and indices that are
+// missing, so shipping a new visibility option or column here is enough to
+// roll it out to existing databases.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Paper{},
+		&WorkspaceUser{},
+		&SiteUser{},
+		&Share{},
+		&RevokedToken{},
+	)
+}
@@ -0,0 +1,77 @@
+// Package models is the persistence layer for Ku Research: GORM-backed
+// tables for papers and the workspace/site memberships that drive access
+// control, plus the repository functions the Fiber handlers call into.
+package models
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DB is the shared database handle used by every repository function in
+// this package. It is populated by Init and must not be used before that
+// call returns successfully.
+var DB *gorm.DB
+
+// Init opens the configured database connection and runs auto-migrations
+// for every model this package owns. The backend is selected with the
+// KU_RESEARCH_DB_DRIVER env var ("sqlite", "postgres", or "mysql") and
+// defaults to SQLite so the service keeps working with zero configuration.
+func Init() error {
+	driver := os.Getenv("KU_RESEARCH_DB_DRIVER")
+	if driver == "" {
+		driver = "sqlite"
+	}
+
+	dialector, err := dialectorFor(driver)
+	if err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return fmt.Errorf("models: open database: %w", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		return fmt.Errorf("models: migrate database: %w", err)
+	}
+
+	DB = db
+	log.Printf("📚 models: connected to %s database\n", driver)
+	return nil
+}
+
+func dialectorFor(driver string) (gorm.Dialector, error) {
+	switch driver {
+	case "sqlite":
+		path := os.Getenv("KU_RESEARCH_DB_PATH")
+		if path == "" {
+			path = "ku-research.db"
+		}
+		return sqlite.Open(path), nil
+	case "postgres":
+		dsn := os.Getenv("KU_RESEARCH_DB_DSN")
+		if dsn == "" {
+			return nil, fmt.Errorf("models: KU_RESEARCH_DB_DSN must be set when KU_RESEARCH_DB_DRIVER=postgres")
+		}
+		return postgres.Open(dsn), nil
+	case "mysql":
+		dsn := os.Getenv("KU_RESEARCH_DB_DSN")
+		if dsn == "" {
+			return nil, fmt.Errorf("models: KU_RESEARCH_DB_DSN must be set when KU_RESEARCH_DB_DRIVER=mysql")
+		}
+		return mysql.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("models: unsupported KU_RESEARCH_DB_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Paper is the persisted form of a research paper, including the
+// visibility rules enforced by GetAccessiblePapers. It deliberately does
+// not embed gorm.Model: that would leak CreatedAt/UpdatedAt/DeletedAt into
+// every JSON response and change ID from the string shape ("id": "1")
+// callers already depend on into an untagged uint.
+type Paper struct {
+	ID              PaperID     `json:"id" gorm:"primarykey"`
+	Title           string      `json:"title" gorm:"not null"`
+	Authors         string      `json:"authors" gorm:"not null"`
+	Abstract        string      `json:"abstract" gorm:"not null"`
+	CoverImage      string      `json:"coverImage"`
+	PublishedYear   int         `json:"publishedYear"`
+	Field           string      `json:"field"`
+	Classifications StringSlice `json:"classifications" gorm:"type:text"`
+	DOI             string      `json:"doi,omitempty"`
+	Journal         string      `json:"journal,omitempty"`
+
+	// Visibility fields
+	UserID          int    `json:"userId" gorm:"index;not null"`
+	IsPublic        bool   `json:"isPublic" gorm:"index"`
+	PublicOption    string `json:"publicOption,omitempty" gorm:"index"` // "workspace", "site", or "everyone"
+	WorkspaceSiteID int    `json:"workspaceSiteID,omitempty" gorm:"index"`
+
+	CreatedAt time.Time      `json:"-"`
+	UpdatedAt time.Time      `json:"-"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// CreatePaper inserts a new paper.
+func CreatePaper(paper *Paper) error {
+	return DB.Create(paper).Error
+}
+
+// GetPaper fetches a single paper by its primary key.
+func GetPaper(id PaperID) (*Paper, error) {
+	var paper Paper
+	if err := DB.First(&paper, id).Error; err != nil {
+		return nil, err
+	}
+	return &paper, nil
+}
+
+// ListByWorkspace returns every paper shared with workspaceID under the
+// "workspace" visibility option.
+func ListByWorkspace(workspaceID int) ([]Paper, error) {
+	var papers []Paper
+	err := DB.Where("is_public = ? AND public_option = ? AND workspace_site_id = ?", true, "workspace", workspaceID).
+		Find(&papers).Error
+	return papers, err
+}
+
+// GetAccessiblePapers returns every paper userID may read: papers they own,
+// plus public papers whose visibility (everyone/site/workspace) includes
+// them. It is expressed as a single query, backed by indices on user_id,
+// is_public, and workspace_site_id, so it scales past a handful of rows.
+func GetAccessiblePapers(userID int) ([]Paper, error) {
+	var papers []Paper
+	err := DB.
+		Where("user_id = ?", userID).
+		Or("is_public = ? AND public_option = ?", true, "everyone").
+		Or("is_public = ? AND public_option = ? AND EXISTS (SELECT 1 FROM site_users su WHERE su.user_id = ? AND su.deleted_at IS NULL)", true, "site", userID).
+		Or("is_public = ? AND public_option = ? AND EXISTS (SELECT 1 FROM workspace_users wu WHERE wu.workspace_id = papers.workspace_site_id AND wu.user_id = ? AND wu.deleted_at IS NULL)", true, "workspace", userID).
+		Find(&papers).Error
+	return papers, err
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevokedToken records a JWT ID (jti) that has been explicitly invalidated
+// before its natural expiry, e.g. on logout. AuthRequired consults this
+// table on every request.
+type RevokedToken struct {
+	gorm.Model
+	JTI       string `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time
+}
+
+// RevokeToken blacklists jti until expiresAt, after which it would have
+// expired on its own anyway.
+func RevokeToken(jti string, expiresAt time.Time) error {
+	return DB.Create(&RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// IsTokenRevoked reports whether jti has been blacklisted.
+func IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := DB.Model(&RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
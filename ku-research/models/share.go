@@ -0,0 +1,105 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrShareExhausted is returned by Redeem when the share has already hit
+// its MaxUses limit.
+var ErrShareExhausted = errors.New("models: share has no uses remaining")
+
+// Share grants time-limited, secret-gated read access to a single paper
+// without adding the recipient to a workspace or flipping the paper to
+// public_option "everyone".
+type Share struct {
+	gorm.Model
+	Token      string  `gorm:"uniqueIndex;not null"`
+	SecretHash string  `gorm:"not null"`
+	PaperID    PaperID `gorm:"index;not null"`
+	CreatedBy  int     `gorm:"index;not null"`
+	ExpiresAt  time.Time
+	MaxUses    int
+	UsedCount  int
+}
+
+// NewShare creates and persists a Share for paperID on behalf of createdBy.
+// It generates a random URL-safe token and a random secret, returning the
+// secret in plaintext since only its bcrypt hash is stored.
+func NewShare(paperID PaperID, createdBy int, ttl time.Duration, maxUses int) (*Share, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", err
+	}
+
+	share := &Share{
+		Token:      token,
+		SecretHash: string(hash),
+		PaperID:    paperID,
+		CreatedBy:  createdBy,
+		ExpiresAt:  time.Now().Add(ttl),
+		MaxUses:    maxUses,
+	}
+	if err := DB.Create(share).Error; err != nil {
+		return nil, "", err
+	}
+	return share, secret, nil
+}
+
+// randomToken returns a 16-byte, URL-safe random string suitable for either
+// a share token or secret.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GetShareByToken looks up a non-expired share by its token. It does not
+// check MaxUses/UsedCount; callers should do that via Redeem.
+func GetShareByToken(token string) (*Share, error) {
+	var share Share
+	err := DB.Where("token = ? AND expires_at > ?", token, time.Now()).First(&share).Error
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// Redeem verifies secret against the share's stored hash and, if it
+// matches, atomically increments its use count, failing with
+// ErrShareExhausted if MaxUses has already been reached. The increment is
+// a single conditional UPDATE rather than a check-then-act on s.UsedCount,
+// so concurrent redemptions of the same share can't all pass a stale check
+// and exceed MaxUses.
+func (s *Share) Redeem(secret string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(s.SecretHash), []byte(secret)); err != nil {
+		return err
+	}
+
+	result := DB.Model(&Share{}).
+		Where("id = ? AND (max_uses = 0 OR used_count < max_uses)", s.ID).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareExhausted
+	}
+	s.UsedCount++
+	return nil
+}
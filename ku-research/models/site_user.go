@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// SiteUser records that a user belongs to site #1, granting access to
+// papers shared with public_option "site".
+type SiteUser struct {
+	gorm.Model
+	UserID int `json:"userId" gorm:"uniqueIndex"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// StringSlice persists a []string as a JSON array so GORM can store it in a
+// single text column without a join table.
+type StringSlice []string
+
+// Value implements driver.Valuer.
+func (s StringSlice) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner.
+func (s *StringSlice) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return errors.New("models: StringSlice.Scan: unsupported type")
+	}
+}
+
+// PaperID is a Paper's primary key. It marshals as a JSON string so moving
+// from the old in-memory slice (where IDs were strings like "1") to an
+// autoincrementing database column doesn't change the wire contract
+// /get-research and /add-paper callers depend on.
+type PaperID uint
+
+// MarshalJSON implements json.Marshaler.
+func (id PaperID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(id), 10))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either a quoted
+// string ("1") or a bare number (1) on the way in.
+func (id *PaperID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		*id = PaperID(n)
+		return nil
+	}
+
+	var n uint64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = PaperID(n)
+	return nil
+}
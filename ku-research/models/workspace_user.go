@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// WorkspaceUser records that a user belongs to a workspace, granting access
+// to papers shared with public_option "workspace" inside that workspace.
+type WorkspaceUser struct {
+	gorm.Model
+	WorkspaceID int `json:"workspaceId" gorm:"index:idx_workspace_user,unique"`
+	UserID      int `json:"userId" gorm:"index:idx_workspace_user,unique"`
+}